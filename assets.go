@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"path/filepath"
+	"strings"
+)
+
+//assetFlag collects repeated -asset path[:label] flags.
+type assetFlag []string
+
+func (f *assetFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *assetFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+//createFlags holds the parsed flags for the "create" subcommand.
+type createFlags struct {
+	assets []string
+	//dryRun renders the composed release to stdout instead of calling
+	//CreateRelease.
+	dryRun bool
+	//yes skips the interactive prompts, taking defaults throughout so
+	//the whole flow can run unattended from CI.
+	yes bool
+}
+
+//parseCreateFlags parses the "create" subcommand's flags and resolves
+//the final list of asset paths (explicit -asset entries plus anything
+//matched by -asset-glob).
+func parseCreateFlags(args []string) (createFlags, error) {
+	fs := flag.NewFlagSet(cmdCreate, flag.ExitOnError)
+	var assets assetFlag
+	fs.Var(&assets, "asset", "path to a release asset to upload, optionally as path:label (repeatable)")
+	assetGlob := fs.String("asset-glob", "", "glob pattern matching release assets to upload")
+	dryRun := fs.Bool("dry-run", false, "render the composed release to stdout instead of creating it")
+	yes := fs.Bool("yes", false, "skip interactive prompts and take defaults (for CI)")
+	if err := fs.Parse(args); err != nil {
+		return createFlags{}, err
+	}
+
+	if *assetGlob != "" {
+		matches, err := filepath.Glob(*assetGlob)
+		if err != nil {
+			return createFlags{}, err
+		}
+		assets = append(assets, matches...)
+	}
+	return createFlags{assets: assets, dryRun: *dryRun, yes: *yes}, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+const configFilename = ".gh-release.yml"
+
+//Config holds user-overridable settings, read from .gh-release.yml in the
+//repo root and falling back to the [gh-release] section of ~/.gitconfig.
+type Config struct {
+	ReleaseNotesTemplate string `yaml:"release_notes_template"`
+}
+
+func loadConfig(gitconfig map[string]map[string]string) (*Config, error) {
+	cfg := &Config{}
+	data, err := ioutil.ReadFile(configFilename)
+	if err == nil {
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if cfg.ReleaseNotesTemplate == "" {
+		if section := gitconfig["gh-release"]; section != nil {
+			cfg.ReleaseNotesTemplate = section["release-notes-template"]
+		}
+	}
+	if cfg.ReleaseNotesTemplate == "" {
+		cfg.ReleaseNotesTemplate = defaultReleaseNotesTemplate
+	}
+	return cfg, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hassansin/gh-release/internal/github"
+)
+
+//dryRunRelease is the machine-readable form of a composed release,
+//printed alongside its Markdown rendering under --dry-run.
+type dryRunRelease struct {
+	Tag        string   `json:"tag"`
+	TargetSHA  string   `json:"target_sha"`
+	Title      string   `json:"title"`
+	Body       string   `json:"body"`
+	Assets     []string `json:"assets"`
+	Draft      bool     `json:"draft"`
+	Prerelease bool     `json:"prerelease"`
+}
+
+//printDryRun writes the composed release to stdout as Markdown followed
+//by a JSON block, for CI previews (e.g. a PR description) that need the
+//rendered notes without actually creating anything on GitHub.
+func printDryRun(release *github.Release, targetSHA string) error {
+	fmt.Printf("# %s\n\n**Target:** `%s`\n\n%s\n\n%s\n", release.Tag.Name, targetSHA, release.Name, release.Description)
+	if len(release.Assets) > 0 {
+		fmt.Println("**Assets:**")
+		for _, asset := range release.Assets {
+			fmt.Printf("- %s\n", asset)
+		}
+		fmt.Println()
+	}
+
+	data, err := json.MarshalIndent(dryRunRelease{
+		Tag:        release.Tag.Name,
+		TargetSHA:  targetSHA,
+		Title:      release.Name,
+		Body:       release.Description,
+		Assets:     release.Assets,
+		Draft:      release.Draft,
+		Prerelease: release.Prerelease,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("```json\n%s\n```\n", data)
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hassansin/gh-release/internal/github"
+	"github.com/manifoldco/promptui"
+	"github.com/pkg/errors"
+)
+
+func runEdit(args []string) {
+	if len(args) == 0 {
+		abort(errors.New("usage: gh-release edit <tag>"))
+	}
+	tag := args[0]
+
+	owner, name, host, _ := mustGetCurrentRepo()
+	editorCmd := mustFindEditor()
+	gitconfig, err := readGitConfig()
+	if err != nil {
+		abort(err)
+	}
+	token := mustGetToken(gitconfig, host)
+	client, err := github.New(owner, name, token, host)
+	if err != nil {
+		abort(err)
+	}
+
+	if err := editRelease(editorCmd, client, tag); err != nil {
+		abort(err)
+	}
+}
+
+func editRelease(editorCmd []string, client github.GithubClient, tag string) error {
+	done := make(chan struct{})
+	go showProgress("fetching release", done)
+	release, err := client.GetRelease(tag)
+	done <- struct{}{}
+	if err != nil {
+		return err
+	}
+
+	ed := newEditor(editorCmd)
+	title, body, err := ed.edit(editReleaseMsg(release))
+	if err != nil {
+		return err
+	}
+	if title == "" {
+		return nil
+	}
+	release.Name = title
+	release.Description = body
+
+	go showProgress("updating release", done)
+	release, err = client.UpdateRelease(release)
+	done <- struct{}{}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%v Release(%v) updated:\n  %v\n", green(promptui.IconGood), cyan(release.Tag.Name), release.HTMLURL)
+	return nil
+}
+
+//editReleaseMsg renders the existing title/body of a release for editing,
+//in the same comment-stripped style as RELEASE_EDITMSG.
+func editReleaseMsg(r *github.Release) string {
+	return fmt.Sprintf(`%v
+%v
+#
+# Please edit the release title as the first line. Lines starting
+# with a single '#' will be ignored, and an empty title aborts the operation.
+#
+# Tag: %v
+`, r.Name, r.Description, r.Tag.Name)
+}
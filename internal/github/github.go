@@ -3,14 +3,23 @@ package github
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 
-	"github.com/google/go-github/github"
+	"github.com/google/go-github/v33/github"
 	"github.com/shurcooL/githubv4"
 	"github.com/shurcooL/graphql"
 	"golang.org/x/oauth2"
 )
 
+//defaultHost is the public GitHub host; anything else is treated as a
+//GitHub Enterprise instance reachable via its /api/v3 and /api/graphql
+//endpoints.
+const defaultHost = "github.com"
+
 type Commit struct {
 	Message string
 	ID      string
@@ -25,10 +34,30 @@ type Tag struct {
 }
 
 type Release struct {
+	ID          int64
 	Name        string
 	Description string
 	Tag         Tag
 	HTMLURL     string
+	Draft       bool
+	Prerelease  bool
+	//Assets is a list of local file paths to attach, each optionally
+	//suffixed with ":label" (e.g. "dist/app.tar.gz:Linux amd64").
+	Assets []string
+	//AssetErrors records the assets that failed to upload during
+	//CreateRelease, in the order they were attempted. The release
+	//itself is still considered created successfully.
+	AssetErrors []AssetError
+}
+
+//AssetError is the upload failure for a single asset path.
+type AssetError struct {
+	Path string
+	Err  error
+}
+
+func (e AssetError) Error() string {
+	return e.Path + ": " + e.Err.Error()
 }
 
 type Branch struct {
@@ -48,31 +77,63 @@ type GithubClient interface {
 	GetRepository() (*Repository, error)
 	CompareCommits(base, head *Commit) ([]*Commit, error)
 	CreateRelease(*Release) (*Release, error)
+	UpdateRelease(*Release) (*Release, error)
+	GetRelease(tag string) (*Release, error)
+	ListReleases() ([]*Release, error)
+	UploadAsset(releaseID int64, asset string) error
 }
 
-func New(owner, name, token string) GithubClient {
+//New builds a GithubClient for owner/name. host selects the API to talk
+//to: "" or "github.com" targets the public API, anything else is
+//treated as a GitHub Enterprise hostname.
+func New(owner, name, token, host string) (GithubClient, error) {
 	ctx := context.Background()
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	client := oauth2.NewClient(ctx, ts)
-	return &Github{
-		client: client,
+	httpClient := oauth2.NewClient(ctx, ts)
+
+	if host == "" {
+		host = defaultHost
+	}
+
+	g := &Github{
 		ctx:    ctx,
+		client: httpClient,
 		owner:  owner,
 		name:   name,
+		host:   host,
 	}
 
+	if host == defaultHost {
+		g.rest = github.NewClient(httpClient)
+		g.graphql = githubv4.NewClient(httpClient)
+		return g, nil
+	}
+
+	rest, err := github.NewEnterpriseClient(
+		fmt.Sprintf("https://%s/api/v3/", host),
+		fmt.Sprintf("https://%s/api/uploads/", host),
+		httpClient,
+	)
+	if err != nil {
+		return nil, err
+	}
+	g.rest = rest
+	g.graphql = githubv4.NewEnterpriseClient(fmt.Sprintf("https://%s/api/graphql", host), httpClient)
+	return g, nil
 }
 
 type Github struct {
-	ctx         context.Context
-	client      *http.Client
-	owner, name string
+	ctx               context.Context
+	client            *http.Client
+	rest              *github.Client
+	graphql           *githubv4.Client
+	owner, name, host string
 }
 
 func (c *Github) GetRepository() (*Repository, error) {
-	client := githubv4.NewClient(c.client)
+	client := c.graphql
 	type RefNode struct {
 		Name   string
 		Target struct {
@@ -171,7 +232,7 @@ func (c *Github) GetRepository() (*Repository, error) {
 }
 
 func (c *Github) CompareCommits(base, head *Commit) ([]*Commit, error) {
-	client := github.NewClient(c.client)
+	client := c.rest
 
 	compare, _, err := client.Repositories.CompareCommits(c.ctx, c.owner, c.name, base.ID, head.ID)
 	if *compare.Status != "ahead" {
@@ -193,13 +254,147 @@ func (c *Github) CreateRelease(r *Release) (*Release, error) {
 	if r.Name == "" || r.Description == "" {
 		return nil, errors.New("empty release title and message")
 	}
-	client := github.NewClient(c.client)
+	client := c.rest
 	rel, _, err := client.Repositories.CreateRelease(c.ctx, c.owner, c.name, &github.RepositoryRelease{
 		Name:            &r.Name,
 		TagName:         &r.Tag.Name,
 		TargetCommitish: &r.Tag.Target.ID,
 		Body:            &r.Description,
+		Draft:           &r.Draft,
+		Prerelease:      &r.Prerelease,
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.ID = *rel.ID
+	r.HTMLURL = *rel.HTMLURL
+
+	for _, asset := range r.Assets {
+		if err := c.UploadAsset(r.ID, asset); err != nil {
+			r.AssetErrors = append(r.AssetErrors, AssetError{Path: asset, Err: err})
+		}
+	}
+	return r, nil
+}
+
+//UpdateRelease PATCHes an existing release's editable fields.
+func (c *Github) UpdateRelease(r *Release) (*Release, error) {
+	if r.Name == "" || r.Description == "" {
+		return nil, errors.New("empty release title and message")
+	}
+	client := c.rest
+	rel, _, err := client.Repositories.EditRelease(c.ctx, c.owner, c.name, r.ID, &github.RepositoryRelease{
+		Name:       &r.Name,
+		Body:       &r.Description,
+		Draft:      &r.Draft,
+		Prerelease: &r.Prerelease,
 	})
+	if err != nil {
+		return nil, err
+	}
 	r.HTMLURL = *rel.HTMLURL
-	return r, err
+	return r, nil
+}
+
+//GetRelease fetches a single release by tag name via GraphQL.
+func (c *Github) GetRelease(tag string) (*Release, error) {
+	client := c.graphql
+	var query struct {
+		Repository struct {
+			Release struct {
+				DatabaseID   int64
+				Name         string
+				Description  string
+				IsDraft      bool
+				IsPrerelease bool
+				Tag          struct {
+					Name   string
+					Target struct {
+						Oid string
+					}
+				}
+			} `graphql:"release(tagName: $tag)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	vars := map[string]interface{}{
+		"owner": graphql.String(c.owner),
+		"name":  graphql.String(c.name),
+		"tag":   graphql.String(tag),
+	}
+	if err := client.Query(c.ctx, &query, vars); err != nil {
+		return nil, err
+	}
+	rel := query.Repository.Release
+	return &Release{
+		ID:          rel.DatabaseID,
+		Name:        rel.Name,
+		Description: rel.Description,
+		Draft:       rel.IsDraft,
+		Prerelease:  rel.IsPrerelease,
+		HTMLURL:     "https://" + c.host + "/" + c.owner + "/" + c.name + "/releases/tag/" + tag,
+		Tag: Tag{
+			Name:   rel.Tag.Name,
+			Target: &Commit{ID: rel.Tag.Target.Oid},
+		},
+	}, nil
+}
+
+//ListReleases returns every release for the repository, most recent first.
+func (c *Github) ListReleases() ([]*Release, error) {
+	client := c.rest
+	var releases []*github.RepositoryRelease
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := client.Repositories.ListReleases(c.ctx, c.owner, c.name, opts)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	var result []*Release
+	for _, rel := range releases {
+		result = append(result, &Release{
+			ID:          rel.GetID(),
+			Name:        rel.GetName(),
+			Description: rel.GetBody(),
+			Draft:       rel.GetDraft(),
+			Prerelease:  rel.GetPrerelease(),
+			HTMLURL:     rel.GetHTMLURL(),
+			Tag:         Tag{Name: rel.GetTagName()},
+		})
+	}
+	return result, nil
+}
+
+//UploadAsset uploads a single local file, named "path[:label]", to the
+//release identified by releaseID. A single asset failing does not
+//affect the release itself, so callers may retry just that path.
+func (c *Github) UploadAsset(releaseID int64, asset string) error {
+	path, label := splitAssetLabel(asset)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	opts := &github.UploadOptions{Name: filepath.Base(path)}
+	if label != "" {
+		opts.Label = label
+	}
+	client := c.rest
+	_, _, err = client.Repositories.UploadReleaseAsset(c.ctx, c.owner, c.name, releaseID, opts, f)
+	return err
+}
+
+//splitAssetLabel splits "path:label" into its parts. The index check
+//avoids misreading a Windows drive letter (e.g. "C:\file") as a label.
+func splitAssetLabel(asset string) (path, label string) {
+	if i := strings.LastIndex(asset, ":"); i > 1 {
+		return asset[:i], asset[i+1:]
+	}
+	return asset, ""
 }
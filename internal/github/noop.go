@@ -0,0 +1,28 @@
+package github
+
+//NoopClient wraps a real GithubClient for CI preview ("--dry-run") use:
+//reads pass through untouched, but anything that would mutate the
+//remote repository is stubbed out. This mirrors hub's --noop convention
+//(args.Noop), which prints what it would have done instead of doing it.
+type NoopClient struct {
+	GithubClient
+}
+
+//NewNoopClient wraps client so that GetRepository and CompareCommits
+//still hit the API, while CreateRelease, UpdateRelease and UploadAsset
+//become no-ops.
+func NewNoopClient(client GithubClient) GithubClient {
+	return &NoopClient{GithubClient: client}
+}
+
+func (c *NoopClient) CreateRelease(r *Release) (*Release, error) {
+	return r, nil
+}
+
+func (c *NoopClient) UpdateRelease(r *Release) (*Release, error) {
+	return r, nil
+}
+
+func (c *NoopClient) UploadAsset(releaseID int64, asset string) error {
+	return nil
+}
@@ -0,0 +1,93 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/hassansin/gh-release/internal/github"
+)
+
+const tagPrefix = "v"
+
+type bump int
+
+const (
+	bumpPatch bump = iota
+	bumpMinor
+	bumpMajor
+)
+
+var reConventional = regexp.MustCompile(`^(\w+)(\([^)]+\))?(!)?:\s*(.*)$`)
+
+// DetermineBump inspects commits for Conventional Commits prefixes and
+// decides whether the next tag should be a major, minor or patch bump
+// over current. It returns the computed tag along with a short human
+// readable reason (e.g. "minor bump from 3 feat commit(s)") describing
+// which rule won.
+func DetermineBump(commits []*github.Commit, current string) (string, string, error) {
+	v, err := semver.Make(strings.TrimPrefix(current, tagPrefix))
+	if err != nil {
+		return "", "", err
+	}
+
+	highest := bumpPatch
+	var majorCount, minorCount, patchCount int
+
+	for _, c := range commits {
+		lines := strings.SplitN(c.Message, "\n", 2)
+		subject := lines[0]
+		body := ""
+		if len(lines) > 1 {
+			body = lines[1]
+		}
+		if isSkipped(subject) {
+			continue
+		}
+
+		m := reConventional.FindStringSubmatch(subject)
+		breaking := strings.Contains(body, "BREAKING CHANGE:") || (m != nil && m[3] == "!")
+
+		switch {
+		case breaking:
+			majorCount++
+			highest = bumpMajor
+		case m != nil && m[1] == "feat":
+			minorCount++
+			if highest < bumpMinor {
+				highest = bumpMinor
+			}
+		default:
+			patchCount++
+		}
+	}
+
+	var reason string
+	switch highest {
+	case bumpMajor:
+		v.Major++
+		v.Minor = 0
+		v.Patch = 0
+		reason = fmt.Sprintf("major bump from %v breaking change commit(s)", majorCount)
+	case bumpMinor:
+		v.Minor++
+		v.Patch = 0
+		reason = fmt.Sprintf("minor bump from %v feat commit(s)", minorCount)
+	default:
+		v.Patch++
+		reason = fmt.Sprintf("patch bump from %v commit(s)", patchCount)
+	}
+
+	tag := v.String()
+	if strings.HasPrefix(current, tagPrefix) {
+		tag = tagPrefix + tag
+	}
+	return tag, reason, nil
+}
+
+// isSkipped reports whether a commit subject marks a release commit
+// that shouldn't trigger its own version bump.
+func isSkipped(subject string) bool {
+	return strings.Contains(subject, "[skip]") || strings.HasPrefix(subject, "chore(release):")
+}
@@ -0,0 +1,71 @@
+package semver
+
+import (
+	"testing"
+
+	"github.com/hassansin/gh-release/internal/github"
+)
+
+func TestDetermineBump(t *testing.T) {
+	testCases := []struct {
+		name     string
+		messages []string
+		current  string
+		tag      string
+		reason   string
+	}{
+		{
+			"patch only",
+			[]string{"fix: off by one", "chore: tidy up"},
+			"v1.2.3",
+			"v1.2.4",
+			"patch bump from 2 commit(s)",
+		},
+		{
+			"feat wins over fix",
+			[]string{"fix: off by one", "feat: add widgets", "feat(api): paging"},
+			"v1.2.3",
+			"v1.3.0",
+			"minor bump from 2 feat commit(s)",
+		},
+		{
+			"bang marks breaking",
+			[]string{"feat!: drop old api", "fix: typo"},
+			"v1.2.3",
+			"v2.0.0",
+			"major bump from 1 breaking change commit(s)",
+		},
+		{
+			"breaking change trailer",
+			[]string{"fix: tweak\n\nBREAKING CHANGE: removes flag"},
+			"1.2.3",
+			"2.0.0",
+			"major bump from 1 breaking change commit(s)",
+		},
+		{
+			"skip markers excluded",
+			[]string{"chore(release): v1.2.4", "fix: [skip] wip", "feat: add widgets"},
+			"v1.2.3",
+			"v1.3.0",
+			"minor bump from 1 feat commit(s)",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var commits []*github.Commit
+			for _, m := range tc.messages {
+				commits = append(commits, &github.Commit{Message: m})
+			}
+			tag, reason, err := DetermineBump(commits, tc.current)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tag != tc.tag {
+				t.Errorf("tag: exp %v, got %v", tc.tag, tag)
+			}
+			if reason != tc.reason {
+				t.Errorf("reason: exp %v, got %v", tc.reason, reason)
+			}
+		})
+	}
+}
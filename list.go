@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hassansin/gh-release/internal/github"
+)
+
+func runList(args []string) {
+	owner, name, host, _ := mustGetCurrentRepo()
+	gitconfig, err := readGitConfig()
+	if err != nil {
+		abort(err)
+	}
+	token := mustGetToken(gitconfig, host)
+	client, err := github.New(owner, name, token, host)
+	if err != nil {
+		abort(err)
+	}
+
+	releases, err := client.ListReleases()
+	if err != nil {
+		abort(err)
+	}
+	for _, r := range releases {
+		mode := "published"
+		if r.Draft {
+			mode = "draft"
+		} else if r.Prerelease {
+			mode = "prerelease"
+		}
+		fmt.Printf("%v\t%v\t%v\n", bold(r.Tag.Name), faint(mode), r.Name)
+	}
+}
@@ -13,24 +13,30 @@ import (
 	"strings"
 	"time"
 
-	"github.com/blang/semver"
 	"github.com/hassansin/gh-release/internal/github"
+	"github.com/hassansin/gh-release/internal/semver"
 	"github.com/manifoldco/promptui"
 	"github.com/pkg/errors"
 )
 
 const (
 	defaultEditor      = "vim"
-	tagPrefix          = "v"
 	releaseMsgFilename = "RELEASE_EDITMSG"
 	lineReset          = "\033[2K\r"
+
+	cmdCreate = "create"
+	cmdEdit   = "edit"
+	cmdShow   = "show"
+	cmdList   = "list"
 )
 
 var (
-	reRepo    = regexp.MustCompile(`[/:]([a-z-]+)/([a-z-]+)`)
-	reSection = regexp.MustCompile(`^\[(.*)\]`)
-	reVal     = regexp.MustCompile(`^\s+(\w+)\s*=\s*(.*)$`)
-	reComment = regexp.MustCompile(`^\s*#`)
+	reRemote         = regexp.MustCompile(`^(?:https?://(?:[^@/]+@)?([^/]+)/|git@([^:]+):)([\w.-]+)/([\w.-]+?)(?:\.git)?\s*$`)
+	reSection        = regexp.MustCompile(`^\[(.*)\]`)
+	reVal            = regexp.MustCompile(`^\s+([\w-]+)\s*=\s*(.*)$`)
+	reComment        = regexp.MustCompile(`^\s*#`)
+	reReleaseComment = regexp.MustCompile(`^\s*#([^#]|$)`)
+	reReleaseHeading = regexp.MustCompile(`^\s*#{2,}\s`)
 
 	bold          = promptui.Styler(promptui.FGBold)
 	cyan          = promptui.Styler(promptui.FGCyan, promptui.FGBold)
@@ -41,11 +47,56 @@ var (
 )
 
 func main() {
-	owner, name, head := mustGetCurrentRepo()
+	cmd, args := parseCommand(os.Args[1:])
+	switch cmd {
+	case cmdEdit:
+		runEdit(args)
+	case cmdShow:
+		runShow(args)
+	case cmdList:
+		runList(args)
+	default:
+		runCreate(args)
+	}
+}
+
+//parseCommand splits the subcommand name off the front of the argument
+//list, defaulting to "create" when it's omitted or the first argument
+//is a flag rather than one of create/edit/show/list.
+func parseCommand(args []string) (string, []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case cmdCreate, cmdEdit, cmdShow, cmdList:
+			return args[0], args[1:]
+		}
+	}
+	return cmdCreate, args
+}
+
+func runCreate(args []string) {
+	cf, err := parseCreateFlags(args)
+	if err != nil {
+		abort(err)
+	}
+	owner, name, host, head := mustGetCurrentRepo()
 	editorCmd := mustFindEditor()
-	token := mustGetToken()
-	client := github.New(owner, name, token)
-	if err := do(editorCmd, client, head); err != nil {
+	gitconfig, err := readGitConfig()
+	if err != nil {
+		abort(err)
+	}
+	token := mustGetToken(gitconfig, host)
+	cfg, err := loadConfig(gitconfig)
+	if err != nil {
+		abort(err)
+	}
+	client, err := github.New(owner, name, token, host)
+	if err != nil {
+		abort(err)
+	}
+	if cf.dryRun {
+		client = github.NewNoopClient(client)
+	}
+	if err := do(editorCmd, client, head, cfg, cf.assets, cf.yes, cf.dryRun); err != nil {
 		abort(err)
 	}
 }
@@ -56,7 +107,7 @@ func abort(err error) {
 	os.Exit(1)
 }
 
-func do(editorCmd []string, client github.GithubClient, head string) error {
+func do(editorCmd []string, client github.GithubClient, head string, cfg *Config, assets []string, yes, dryRun bool) error {
 	done := make(chan struct{})
 	go showProgress("getting current release", done)
 
@@ -83,42 +134,69 @@ func do(editorCmd []string, client github.GithubClient, head string) error {
 			faint("Commit: "+repo.LatestRelease.Tag.Target.ShortID+" "+repo.LatestRelease.Tag.Target.Message))
 	*/
 
-	target, err := selectTarget(repo.Branches, repo.LatestRelease)
-	if err != nil || target == nil {
-		return err
+	var target *github.Branch
+	if yes {
+		//sortBranches put head first; take it as the default target.
+		target = repo.Branches[0]
+	} else {
+		target, err = selectTarget(repo.Branches, repo.LatestRelease)
+		if err != nil || target == nil {
+			return err
+		}
 	}
-	lastRel := repo.LatestRelease.Tag.Name
-	version, err := nextVersion(lastRel)
-	if err != nil {
-		return err
+
+	var draft, prerelease bool
+	if !yes {
+		draft, prerelease, err = selectReleaseMode()
+		if err != nil {
+			return err
+		}
 	}
-	var commits []*github.Commit
-	errCh := make(chan error)
 
-	go func() {
-		commits, err = client.CompareCommits(repo.LatestRelease.Tag.Target, target.Head)
-		errCh <- err
-	}()
+	lastRel := repo.LatestRelease.Tag.Name
 
-	tagName, err := promptTag(version, lastRel)
-	if err != nil || tagName == "" {
+	go showProgress("comparing commits", done)
+	commits, err := client.CompareCommits(repo.LatestRelease.Tag.Target, target.Head)
+	done <- struct{}{}
+	if err != nil {
 		return err
 	}
+	if len(commits) == 0 {
+		return errors.Errorf("%v is already released", cyan(target.Name))
+	}
 
-	if err := <-errCh; err != nil {
+	version, reason, err := semver.DetermineBump(commits, lastRel)
+	if err != nil {
 		return err
 	}
 
-	if len(commits) == 0 {
-		return errors.Errorf("%v is already released", cyan(target.Name))
+	tagName := version
+	if !yes {
+		tagName, err = promptTag(version, lastRel, reason)
+		if err != nil || tagName == "" {
+			return err
+		}
 	}
 
-	ed := newEditor(editorCmd)
-
-	title, body, err := ed.edit(releaseNotes(tagName, commits))
+	notes, err := releaseNotes(cfg.ReleaseNotesTemplate, tagName, commits, assets)
 	if err != nil {
 		return err
 	}
+
+	var title, body string
+	if yes {
+		title = tagName
+		body = stripReleaseMsgComments(notes)
+	} else {
+		ed := newEditor(editorCmd)
+		title, body, err = ed.edit(notes)
+		if err != nil {
+			return err
+		}
+		if title == "" {
+			return errors.New("empty title, aborting")
+		}
+	}
 	go showProgress("creating release", done)
 	release, err := client.CreateRelease(&github.Release{
 		Name: title,
@@ -127,27 +205,71 @@ func do(editorCmd []string, client github.GithubClient, head string) error {
 			Target: target.Head,
 		},
 		Description: body,
+		Assets:      assets,
+		Draft:       draft,
+		Prerelease:  prerelease,
 	})
 	done <- struct{}{}
 	if err != nil {
 		return err
 	}
 
+	if dryRun {
+		return printDryRun(release, target.Head.ID)
+	}
+
+	if err := retryFailedAssets(client, release, yes); err != nil {
+		return err
+	}
+
 	fmt.Printf("%v New release(%v) created:\n  %v\n", green(promptui.IconGood), cyan(release.Tag.Name), release.HTMLURL)
 	return nil
 }
 
-func nextVersion(tag string) (string, error) {
-	v, err := semver.Make(strings.TrimPrefix(tag, tagPrefix))
-	if err != nil {
-		return "", err
+//retryFailedAssets prints a summary of any assets that failed to
+//upload and, while the user wants to, retries them one more round. In
+//--yes mode the confirm prompt is skipped (it would block forever in
+//CI); it prints the summary and returns an error instead, so the
+//failure is still visible in the exit code.
+func retryFailedAssets(client github.GithubClient, release *github.Release, yes bool) error {
+	for len(release.AssetErrors) > 0 {
+		fmt.Printf("%v %v\n", promptui.IconBad, white("Some assets failed to upload:"))
+		for _, assetErr := range release.AssetErrors {
+			fmt.Printf("  %v: %v\n", cyan(assetErr.Path), assetErr.Err)
+		}
+		if yes {
+			return errors.Errorf("%d asset(s) failed to upload", len(release.AssetErrors))
+		}
+		retry, err := promptConfirm("Retry failed uploads?")
+		if err != nil {
+			return err
+		}
+		if !retry {
+			return nil
+		}
+		failed := release.AssetErrors
+		release.AssetErrors = nil
+		for _, assetErr := range failed {
+			if err := client.UploadAsset(release.ID, assetErr.Path); err != nil {
+				release.AssetErrors = append(release.AssetErrors, github.AssetError{Path: assetErr.Path, Err: err})
+			}
+		}
 	}
-	v.Patch++
-	version := v.String()
-	if strings.HasPrefix(tag, tagPrefix) {
-		version = tagPrefix + version
+	return nil
+}
+
+func promptConfirm(label string) (bool, error) {
+	prompt := promptui.Prompt{
+		Label:     label,
+		IsConfirm: true,
+	}
+	if _, err := prompt.Run(); err != nil {
+		if err == promptui.ErrAbort {
+			return false, nil
+		}
+		return false, err
 	}
-	return version, nil
+	return true, nil
 }
 
 //sort branches by branch name length, keeping head at the top
@@ -165,12 +287,12 @@ func sortBranches(branches []*github.Branch, head string) {
 	})
 }
 
-func promptTag(tag, lastRel string) (string, error) {
+func promptTag(tag, lastRel, reason string) (string, error) {
 	templates := &promptui.PromptTemplates{
 		Success: fmt.Sprintf(`{{ "%s" | green | bold }} {{"%s" | bold}} %v`, promptui.IconGood, "Tag:", startBoldCyan),
 	}
 	prompt := promptui.Prompt{
-		Label:     fmt.Sprintf("Enter release tag %s", faint("(last release: "+cyan(lastRel)+")")),
+		Label:     fmt.Sprintf("Enter release tag %s", faint("(last release: "+cyan(lastRel)+", "+reason+")")),
 		AllowEdit: true,
 		Default:   tag,
 		Templates: templates,
@@ -230,35 +352,65 @@ func selectTarget(branches []*github.Branch, rel *github.Release) (*github.Branc
 	return branches[i], nil
 }
 
-func mustGetToken() string {
-	token, err := getToken()
+//selectReleaseMode asks whether the release should be published right
+//away, saved as a draft, or marked as a prerelease.
+func selectReleaseMode() (draft bool, prerelease bool, err error) {
+	options := []string{"Publish", "Save as draft", "Mark as prerelease"}
+
+	templates := &promptui.SelectTemplates{
+		Selected: fmt.Sprintf(`{{ "%s" | green | bold }} {{"%s" | bold}} {{. | cyan | bold }}`, promptui.IconGood, "Release mode:"),
+	}
+
+	prompt := promptui.Select{
+		Label:     "Choose a release mode",
+		Items:     options,
+		Templates: templates,
+	}
+
+	i, _, err := prompt.Run()
+	if err == promptui.ErrInterrupt || err == promptui.ErrEOF {
+		return false, false, nil
+	} else if err != nil {
+		return false, false, err
+	}
+	switch options[i] {
+	case "Save as draft":
+		return true, false, nil
+	case "Mark as prerelease":
+		return false, true, nil
+	}
+	return false, false, nil
+}
+
+//mustGetToken resolves a token via resolveToken's source chain and logs
+//(faintly) which source and host it came from.
+func mustGetToken(gitconfig map[string]map[string]string, host string) string {
+	token, source, err := resolveToken(gitconfig, host)
 	if err != nil {
 		abort(err)
 	}
 	if token == "" {
-		abort(errors.New("token not found in your gitconfig file"))
+		abort(errors.New("no token found: set $GH_RELEASE_TOKEN, run `gh auth login`, or add github.token to ~/.gitconfig"))
 	}
+	fmt.Println(faint(fmt.Sprintf("using token from %s (%s)", source, host)))
 	return token
 }
 
-func getToken() (string, error) {
+//readGitConfig parses the current user's ~/.gitconfig
+func readGitConfig() (map[string]map[string]string, error) {
 	u, err := user.Current()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	gitconfigPath := filepath.Join(u.HomeDir, string(filepath.Separator), ".gitconfig")
 	data, err := ioutil.ReadFile(gitconfigPath)
 	if err != nil {
-		return "", err
-	}
-	config := parseConfig(data)
-	if config["github"] == nil {
-		return "", nil
+		return nil, err
 	}
-	return config["github"]["token"], nil
+	return parseConfig(data), nil
 }
 
-func mustGetCurrentRepo() (owner string, repo string, head string) {
+func mustGetCurrentRepo() (owner string, repo string, host string, head string) {
 	mustBeGitRepo()
 	cmd := exec.Command("git", "ls-remote", "--get-url", "origin")
 	var out []byte
@@ -267,8 +419,12 @@ func mustGetCurrentRepo() (owner string, repo string, head string) {
 	if err != nil {
 		panic(err)
 	}
-	if m := reRepo.FindStringSubmatch(string(out)); m != nil {
-		owner, repo = m[1], m[2]
+	if m := reRemote.FindStringSubmatch(string(out)); m != nil {
+		host = m[1] + m[2] //only one of the two alternatives ever matches
+		owner, repo = m[3], m[4]
+	}
+	if host == "" {
+		host = "github.com"
 	}
 	cmd = exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
 	out, err = cmd.Output()
@@ -309,6 +465,14 @@ func isComment(line string) bool {
 	return reComment.MatchString(line)
 }
 
+//isReleaseMsgComment reports whether line is an editor-instruction
+//comment in RELEASE_EDITMSG. Unlike isComment, a markdown heading like
+//"### Features" (multiple '#') is not treated as a comment, since the
+//categorized release notes rely on it surviving the edit round-trip.
+func isReleaseMsgComment(line string) bool {
+	return reReleaseComment.MatchString(line)
+}
+
 func parseConfig(data []byte) map[string]map[string]string {
 	config := make(map[string]map[string]string)
 	lines := strings.Split(string(data), "\n")
@@ -330,24 +494,6 @@ func parseConfig(data []byte) map[string]map[string]string {
 	return config
 }
 
-func releaseNotes(title string, commits []*github.Commit) string {
-	notes := ""
-	for i := len(commits) - 1; i >= 0; i-- {
-		c := commits[i]
-		lines := strings.Split(c.Message, "\n")
-		notes += fmt.Sprintf("#* [%v] - %v (%v)\n", c.ShortID, lines[0], c.Author)
-	}
-	return fmt.Sprintf(`#%v
-#
-# Please enter the realease title as the first line. Lines starting
-# with '#' will be ignored, and an empty title & message aborts the operation.
-# By removing starting '#' of lines below, you can put them in release body.
-#
-#**Commits**
-#
-%v`, title, notes)
-}
-
 func mustFindEditor() []string {
 	env := os.Getenv("EDITOR")
 	if env == "" {
@@ -404,16 +550,33 @@ func parseReleaseMsg(data []byte) (string, string) {
 	lines := strings.Split(string(data), "\n")
 	newLines := lines[:0]
 	for _, line := range lines {
-		if isComment(line) {
+		if isReleaseMsgComment(line) {
 			continue
 		}
 		newLines = append(newLines, line)
 	}
-	if len(newLines) == 0 {
+	if len(newLines) == 0 || reReleaseHeading.MatchString(newLines[0]) {
+		//a leading "### Features"-style heading means the user left the
+		//generated section titles untouched without entering a title.
 		return "", ""
 	}
 	return newLines[0], strings.TrimSpace(strings.Join(newLines[1:], "\n"))
 }
+
+//stripReleaseMsgComments drops the editor-instruction comments from a
+//generated release message, for --yes runs that skip the editor
+//round-trip entirely and use the notes verbatim as the release body.
+func stripReleaseMsgComments(data string) string {
+	lines := strings.Split(data, "\n")
+	newLines := lines[:0]
+	for _, line := range lines {
+		if isReleaseMsgComment(line) {
+			continue
+		}
+		newLines = append(newLines, line)
+	}
+	return strings.TrimSpace(strings.Join(newLines, "\n"))
+}
 func showProgress(msg string, done chan struct{}) {
 	progress := []string{"⣾", "⣽", "⣻", "⢿", "⡿", "⣟", "⣯", "⣷"}
 	defer fmt.Print(faint(lineReset))
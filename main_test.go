@@ -80,6 +80,14 @@ some more text`),
 			"",
 			"",
 		},
+		{
+			"leading heading, no title entered",
+			[]byte(`### Features
+
+* add paging (abc1234)`),
+			"",
+			"",
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
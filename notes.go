@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/hassansin/gh-release/internal/github"
+)
+
+var (
+	reConventionalSubject = regexp.MustCompile(`^(\w+)(\(([^)]+)\))?(!)?:\s*(.*)$`)
+	reIssueTrailer        = regexp.MustCompile(`(?m)^\s*(Closes|Fixes)\s+(#\d+)\s*$`)
+)
+
+//defaultReleaseNotesTemplate groups commits by Conventional Commits type.
+//All sections, BREAKING CHANGES included, are rendered uncommented so
+//they're ready to ship as-is; override via Config.ReleaseNotesTemplate
+//to customize.
+const defaultReleaseNotesTemplate = `#{{.Tag}}
+#
+# Please enter the release title as the first line. Lines starting
+# with a single '#' will be ignored, and an empty title & message aborts the operation.
+#
+{{if .Assets}}
+# Assets to be attached:
+{{range .Assets}}#   - {{.}}
+{{end -}}
+#
+{{end -}}
+{{- define "section"}}{{range .}}* {{if .Scope}}**{{.Scope}}:** {{end}}{{.Subject}} ({{.ShortID}}){{range .Issues}} {{.}}{{end}}
+{{end}}{{end -}}
+{{if .Breaking}}### BREAKING CHANGES
+
+{{template "section" .Breaking}}
+{{end -}}
+{{if .Features}}### Features
+
+{{template "section" .Features}}
+{{end -}}
+{{if .BugFixes}}### Bug Fixes
+
+{{template "section" .BugFixes}}
+{{end -}}
+{{if .Performance}}### Performance
+
+{{template "section" .Performance}}
+{{end -}}
+{{if .Other}}### Other
+
+{{template "section" .Other}}
+{{end -}}
+`
+
+type noteItem struct {
+	ShortID string
+	Scope   string
+	Subject string
+	Issues  []string
+}
+
+type releaseNoteSections struct {
+	Tag         string
+	Assets      []string
+	Breaking    []noteItem
+	Features    []noteItem
+	BugFixes    []noteItem
+	Performance []noteItem
+	Other       []noteItem
+}
+
+//releaseNotes renders tmpl (a text/template) with commits grouped by
+//Conventional Commits type into sections.
+func releaseNotes(tmpl, tag string, commits []*github.Commit, assets []string) (string, error) {
+	t, err := template.New("release-notes").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	sections := buildReleaseNoteSections(tag, commits)
+	sections.Assets = assets
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, sections); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func buildReleaseNoteSections(tag string, commits []*github.Commit) releaseNoteSections {
+	sections := releaseNoteSections{Tag: tag}
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+		lines := strings.SplitN(c.Message, "\n", 2)
+		subject := lines[0]
+		body := ""
+		if len(lines) > 1 {
+			body = lines[1]
+		}
+
+		item := noteItem{ShortID: c.ShortID, Subject: subject, Issues: parseIssueTrailers(body)}
+
+		typ := "other"
+		breaking := strings.Contains(body, "BREAKING CHANGE:")
+		if m := reConventionalSubject.FindStringSubmatch(subject); m != nil {
+			typ = m[1]
+			item.Scope = m[3]
+			item.Subject = m[5]
+			breaking = breaking || m[4] == "!"
+		}
+
+		switch {
+		case breaking:
+			sections.Breaking = append(sections.Breaking, item)
+		case typ == "feat":
+			sections.Features = append(sections.Features, item)
+		case typ == "fix":
+			sections.BugFixes = append(sections.BugFixes, item)
+		case typ == "perf":
+			sections.Performance = append(sections.Performance, item)
+		default:
+			sections.Other = append(sections.Other, item)
+		}
+	}
+	return sections
+}
+
+func parseIssueTrailers(body string) []string {
+	var issues []string
+	for _, m := range reIssueTrailer.FindAllStringSubmatch(body, -1) {
+		issues = append(issues, m[1]+" "+m[2])
+	}
+	return issues
+}
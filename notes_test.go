@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hassansin/gh-release/internal/github"
+)
+
+func TestReleaseNotes(t *testing.T) {
+	commits := []*github.Commit{
+		{ShortID: "def5678", Message: "fix: off by one"},
+		{ShortID: "abc1234", Message: "feat(api): add paging\n\nCloses #123"},
+		{ShortID: "ghi9012", Message: "feat!: drop old endpoint"},
+	}
+	notes, err := releaseNotes(defaultReleaseNotesTemplate, "v1.3.0", commits, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"\n### BREAKING CHANGES\n",
+		"* drop old endpoint (ghi9012)",
+		"\n### Features\n",
+		"* **api:** add paging (abc1234) Closes #123",
+		"\n### Bug Fixes\n",
+		"* off by one (def5678)",
+	} {
+		if !strings.Contains(notes, want) {
+			t.Errorf("expected notes to contain %q, got:\n%v", want, notes)
+		}
+	}
+	if strings.Contains(notes, "####") {
+		t.Errorf("expected no corrupted headings, got:\n%v", notes)
+	}
+}
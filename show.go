@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hassansin/gh-release/internal/github"
+	"github.com/pkg/errors"
+)
+
+func runShow(args []string) {
+	if len(args) == 0 {
+		abort(errors.New("usage: gh-release show <tag>"))
+	}
+	tag := args[0]
+
+	owner, name, host, _ := mustGetCurrentRepo()
+	gitconfig, err := readGitConfig()
+	if err != nil {
+		abort(err)
+	}
+	token := mustGetToken(gitconfig, host)
+	client, err := github.New(owner, name, token, host)
+	if err != nil {
+		abort(err)
+	}
+
+	release, err := client.GetRelease(tag)
+	if err != nil {
+		abort(err)
+	}
+	printRelease(release)
+}
+
+func printRelease(r *github.Release) {
+	mode := "published"
+	if r.Draft {
+		mode = "draft"
+	} else if r.Prerelease {
+		mode = "prerelease"
+	}
+	fmt.Printf("%v %v %v\n", bold(r.Tag.Name), faint("("+mode+")"), cyan(r.HTMLURL))
+	fmt.Printf("%v\n\n", white(r.Name))
+	fmt.Println(r.Description)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+//ghHostsConfig mirrors the relevant bits of gh CLI's ~/.config/gh/hosts.yml.
+type ghHostsConfig map[string]struct {
+	OAuthToken string `yaml:"oauth_token"`
+}
+
+//resolveToken walks the token source chain, in priority order:
+//$GH_RELEASE_TOKEN / $GITHUB_TOKEN, gh CLI's hosts.yml, ~/.gitconfig,
+//and finally "git credential fill". It returns the token along with a
+//description of where it came from, for the faint log line in mustGetToken.
+func resolveToken(gitconfig map[string]map[string]string, host string) (token, source string, err error) {
+	if t := os.Getenv("GH_RELEASE_TOKEN"); t != "" {
+		return t, "$GH_RELEASE_TOKEN", nil
+	}
+	if t := os.Getenv("GITHUB_TOKEN"); t != "" {
+		return t, "$GITHUB_TOKEN", nil
+	}
+
+	t, err := tokenFromGhCLI(host)
+	if err != nil {
+		return "", "", err
+	}
+	if t != "" {
+		return t, "gh CLI config (~/.config/gh/hosts.yml)", nil
+	}
+
+	if gitconfig["github"] != nil && gitconfig["github"]["token"] != "" {
+		return gitconfig["github"]["token"], "~/.gitconfig", nil
+	}
+
+	t, err = tokenFromGitCredential(host)
+	if err != nil {
+		return "", "", err
+	}
+	if t != "" {
+		return t, "git credential fill", nil
+	}
+
+	return "", "", nil
+}
+
+func tokenFromGhCLI(host string) (string, error) {
+	u, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(u.HomeDir, ".config", "gh", "hosts.yml")
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	var hosts ghHostsConfig
+	if err := yaml.Unmarshal(data, &hosts); err != nil {
+		return "", err
+	}
+	return hosts[host].OAuthToken, nil
+}
+
+func tokenFromGitCredential(host string) (string, error) {
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=https\nhost=%s\n\n", host))
+	out, err := cmd.Output()
+	if err != nil {
+		//no credential helper configured for this host; not fatal
+		return "", nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "password=") {
+			return strings.TrimPrefix(line, "password="), nil
+		}
+	}
+	return "", nil
+}